@@ -1,15 +1,26 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"github.com/Masterminds/sprig/v3"
+	"github.com/hashicorp/vault/api"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
 	"io"
+	"io/fs"
+	"net/http"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"plugin"
 	"strconv"
 	"strings"
 	"text/template"
+	"time"
 	"unicode/utf8"
 )
 
@@ -26,8 +37,69 @@ func (i *VarDefsFlag) Set(value string) error {
 
 var varDefs VarDefsFlag
 
+type ValuesFilesFlag []string
+
+func (i *ValuesFilesFlag) String() string {
+	return "my string representation"
+}
+
+func (i *ValuesFilesFlag) Set(value string) error {
+	*i = append(*i, value)
+	return nil
+}
+
+var valuesFiles ValuesFilesFlag
+
+type TemplateDirsFlag []string
+
+func (i *TemplateDirsFlag) String() string {
+	return "my string representation"
+}
+
+func (i *TemplateDirsFlag) Set(value string) error {
+	*i = append(*i, value)
+	return nil
+}
+
+var templateDirs TemplateDirsFlag
+var templateName string
+
+type FuncPluginsFlag []string
+
+func (i *FuncPluginsFlag) String() string {
+	return "my string representation"
+}
+
+func (i *FuncPluginsFlag) Set(value string) error {
+	*i = append(*i, value)
+	return nil
+}
+
+var funcPlugins FuncPluginsFlag
+
+type FuncExecsFlag []string
+
+func (i *FuncExecsFlag) String() string {
+	return "my string representation"
+}
+
+func (i *FuncExecsFlag) Set(value string) error {
+	*i = append(*i, value)
+	return nil
+}
+
+var funcExecs FuncExecsFlag
+
 func init() {
 	flag.Var(&varDefs, "d", "Define variables, syntax: NAME[:TYPE[:TYPE]]=VALUE")
+	flag.Var(&valuesFiles, "V", "Load variables from a YAML/JSON/TOML file, syntax: [FORMAT:]FILE (repeatable)")
+	flag.Var(&valuesFiles, "values", "Load variables from a YAML/JSON/TOML file, syntax: [FORMAT:]FILE (repeatable)")
+	flag.Var(&templateDirs, "t", "Parse every *.tmpl file in this directory into the template set, for {{ template }}/{{ block }} (repeatable)")
+	flag.Var(&templateDirs, "template-dir", "Parse every *.tmpl file in this directory into the template set, for {{ template }}/{{ block }} (repeatable)")
+	flag.StringVar(&templateName, "n", "", "Name of the template to execute (default: the template parsed from -f)")
+	flag.StringVar(&templateName, "name", "", "Name of the template to execute (default: the template parsed from -f)")
+	flag.Var(&funcPlugins, "func-plugin", "Load template functions from a Go plugin exporting Funcs() template.FuncMap (repeatable)")
+	flag.Var(&funcExecs, "func-exec", "Register a template function that shells out to a command, syntax: NAME=CMD (repeatable)")
 }
 
 var inputFile = flag.String("f", "-", "Input file name or - for stdin")
@@ -35,6 +107,15 @@ var outputFile = flag.String("o", "-", "Output file name or - for stdout")
 var versionFlag = flag.Bool("v", false, "Print the version")
 var version = "v1.x.x"
 
+var inDir = flag.String("in-dir", "", "Render every file in this directory tree as a template, mirrored into --out-dir (mutually exclusive with -f/-o)")
+var outDir = flag.String("out-dir", "", "Output directory for --in-dir")
+var suffix = flag.String("suffix", "", "Strip this suffix from output file names in --in-dir mode, e.g. .tmpl")
+
+var strictFlag = flag.Bool("strict", false, "Fail when the template references a context key that isn't defined (sets missingkey=error)")
+var schemaFile = flag.String("schema", "", "Validate the assembled context map against this JSON Schema file before executing the template")
+
+var httpTimeout = flag.Duration("http-timeout", 30*time.Second, "Timeout for the http type parser")
+
 func fatalf(format string, args ...interface{}) {
 	err := fmt.Errorf(format, args...)
 	os.Stderr.WriteString(err.Error() + "\n")
@@ -93,6 +174,75 @@ var typeParsers = map[string]TypeParser{
 	"env": func(input string) (output interface{}, err error) {
 		return os.Getenv(input), nil
 	},
+	"exec": func(input string) (output interface{}, err error) {
+		cmd := exec.Command("sh", "-c", input)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("command %q failed: %w (stderr: %s)", input, err, stderr.String())
+		}
+		return strings.TrimRight(stdout.String(), "\n"), nil
+	},
+	"http": func(input string) (output interface{}, err error) {
+		client := &http.Client{Timeout: *httpTimeout}
+		resp, err := client.Get(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed requesting %q: %w", input, err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading response from %q: %w", input, err)
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return nil, fmt.Errorf("request to %q returned status %d: %s", input, resp.StatusCode, body)
+		}
+		return string(body), nil
+	},
+	"vault": func(input string) (output interface{}, err error) {
+		return vaultTypeParser(input)
+	},
+}
+
+// vaultTypeParser reads "path#field" from a HashiCorp Vault instance
+// configured via the VAULT_ADDR/VAULT_TOKEN environment variables.
+func vaultTypeParser(input string) (interface{}, error) {
+	pathAndField := strings.SplitN(input, "#", 2)
+	if len(pathAndField) != 2 {
+		return nil, fmt.Errorf("vault type parser format is: path#field")
+	}
+	path, field := pathAndField[0], pathAndField[1]
+
+	client, err := api.NewClient(api.DefaultConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed creating vault client: %w", err)
+	}
+	client.SetToken(os.Getenv("VAULT_TOKEN"))
+
+	secret, err := client.Logical().Read(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading vault secret %q: %w", path, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("vault secret %q not found", path)
+	}
+
+	data := secret.Data
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		// kv v2 engines nest the actual fields one level deeper, under "data"
+		data = inner
+	}
+
+	value, ok := data[field]
+	if !ok {
+		return nil, fmt.Errorf("vault secret %q has no field %q", path, field)
+	}
+	str, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("vault secret %q field %q is not a string: %T", path, field, value)
+	}
+	return str, nil
 }
 
 func parseVariableDefinition(spec string) (string, interface{}, error) {
@@ -133,6 +283,281 @@ func parseVariableDefinition(spec string) (string, interface{}, error) {
 	}
 }
 
+// splitValuesFileSpec splits a -V spec of the form "[FORMAT:]FILE" into its
+// format override and path. When no override is given, format is "".
+func splitValuesFileSpec(spec string) (format, path string) {
+	if i := strings.IndexByte(spec, ':'); i != -1 {
+		switch spec[:i] {
+		case "yaml", "yml", "json", "toml":
+			return spec[:i], spec[i+1:]
+		}
+	}
+	return "", spec
+}
+
+// loadValuesFile reads a YAML/JSON/TOML file and unmarshals it into a plain
+// map, detecting the format from the file extension unless overridden.
+func loadValuesFile(spec string) (map[string]interface{}, error) {
+	format, path := splitValuesFileSpec(spec)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading values file: %w", err)
+	}
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+
+	values := map[string]interface{}{}
+	switch format {
+	case "yaml", "yml":
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("failed parsing yaml values file %q: %w", path, err)
+		}
+	case "json":
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("failed parsing json values file %q: %w", path, err)
+		}
+	case "toml":
+		if err := toml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("failed parsing toml values file %q: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported values file format: %q (use yaml, json or toml)", format)
+	}
+	return values, nil
+}
+
+// loadPluginFuncs opens a Go plugin and calls its exported Funcs() function
+// to obtain the template.FuncMap it contributes.
+func loadPluginFuncs(path string) (template.FuncMap, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed opening func plugin %q: %w", path, err)
+	}
+	sym, err := p.Lookup("Funcs")
+	if err != nil {
+		return nil, fmt.Errorf("func plugin %q does not export Funcs: %w", path, err)
+	}
+	fn, ok := sym.(func() template.FuncMap)
+	if !ok {
+		return nil, fmt.Errorf("func plugin %q: Funcs has unexpected signature %T", path, sym)
+	}
+	return fn(), nil
+}
+
+// execTemplateFunc returns a template function that runs cmdline through
+// the shell, passing its arguments as a JSON array on stdin and parsing
+// stdout as JSON, falling back to a plain string if stdout isn't valid JSON.
+func execTemplateFunc(cmdline string) func(args ...interface{}) (interface{}, error) {
+	return func(args ...interface{}) (interface{}, error) {
+		input, err := json.Marshal(args)
+		if err != nil {
+			return nil, fmt.Errorf("failed marshaling args for %q: %w", cmdline, err)
+		}
+
+		cmd := exec.Command("sh", "-c", cmdline)
+		cmd.Stdin = bytes.NewReader(input)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("command %q failed: %w (stderr: %s)", cmdline, err, stderr.String())
+		}
+
+		out := bytes.TrimSpace(stdout.Bytes())
+		var result interface{}
+		if err := json.Unmarshal(out, &result); err == nil {
+			return result, nil
+		}
+		return string(out), nil
+	}
+}
+
+// buildFuncMap assembles the template.FuncMap available to templates: the
+// sprig functions, plus anything contributed by -func-plugin and -func-exec.
+func buildFuncMap() template.FuncMap {
+	funcMap := sprig.TxtFuncMap()
+
+	for _, fp := range funcPlugins {
+		pluginFuncs, err := loadPluginFuncs(string(fp))
+		if err != nil {
+			fatalf("%w", err)
+		}
+		for name, fn := range pluginFuncs {
+			funcMap[name] = fn
+		}
+	}
+
+	for _, fe := range funcExecs {
+		nameAndCmd := strings.SplitN(string(fe), "=", 2)
+		if len(nameAndCmd) != 2 {
+			fatalf("--func-exec format is: NAME=CMD")
+		}
+		funcMap[nameAndCmd[0]] = execTemplateFunc(nameAndCmd[1])
+	}
+
+	return funcMap
+}
+
+// validateContextSchema validates the assembled context map against the
+// JSON Schema file named by -schema, returning a descriptive error that
+// lists every field that failed.
+func validateContextSchema(context map[string]interface{}) error {
+	absPath, err := filepath.Abs(*schemaFile)
+	if err != nil {
+		return fmt.Errorf("failed resolving schema path %q: %w", *schemaFile, err)
+	}
+
+	schemaLoader := gojsonschema.NewReferenceLoader("file://" + absPath)
+	documentLoader := gojsonschema.NewGoLoader(context)
+
+	result, err := gojsonschema.Validate(schemaLoader, documentLoader)
+	if err != nil {
+		return fmt.Errorf("failed validating context against schema %q: %w", *schemaFile, err)
+	}
+	if !result.Valid() {
+		var msgs []string
+		for _, re := range result.Errors() {
+			msgs = append(msgs, re.String())
+		}
+		return fmt.Errorf("context does not match schema %q:\n  %s", *schemaFile, strings.Join(msgs, "\n  "))
+	}
+	return nil
+}
+
+// parseTemplateDirs parses every *.tmpl file from each -t/--template-dir
+// into tpl's template set, so they can reference each other via
+// {{ template }}/{{ block }}.
+func parseTemplateDirs(tpl *template.Template) (*template.Template, error) {
+	for _, dir := range templateDirs {
+		matches, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+		if err != nil {
+			return nil, fmt.Errorf("error globbing template dir %q: %w", dir, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no *.tmpl files found in template dir %q", dir)
+		}
+		if tpl, err = tpl.ParseFiles(matches...); err != nil {
+			return nil, fmt.Errorf("error parsing templates in %q: %w", dir, err)
+		}
+	}
+	return tpl, nil
+}
+
+// buildContext merges -V/--values files and -d variable definitions into a
+// single template context, with -d definitions taking precedence.
+func buildContext() map[string]interface{} {
+	context := map[string]interface{}{}
+	for _, vf := range valuesFiles {
+		values, err := loadValuesFile(string(vf))
+		if err != nil {
+			fatalf("error loading values file %q: %w", vf, err)
+		}
+		for k, v := range values {
+			context[k] = v
+		}
+	}
+
+	for _, vd := range varDefs {
+		name, val, err := parseVariableDefinition(string(vd))
+		if err != nil {
+			fatalf("error parsing variable definition %q: %w", vd, err)
+		}
+		context[name] = val
+	}
+
+	if *schemaFile != "" {
+		if err := validateContextSchema(context); err != nil {
+			fatalf("%w", err)
+		}
+	}
+	return context
+}
+
+// renderDirTree walks *inDir, renders every text file it finds as a
+// template with the given context and writes the result to the mirrored
+// path under *outDir. Binary files are copied through unchanged.
+func renderDirTree(context map[string]interface{}) {
+	inAbs, err := filepath.Abs(*inDir)
+	if err != nil {
+		fatalf("error resolving --in-dir %q: %w", *inDir, err)
+	}
+	outAbs, err := filepath.Abs(*outDir)
+	if err != nil {
+		fatalf("error resolving --out-dir %q: %w", *outDir, err)
+	}
+	if outAbs == inAbs || strings.HasPrefix(outAbs, inAbs+string(filepath.Separator)) {
+		fatalf("--out-dir %q must not be --in-dir %q or a directory inside it, or the walk will render its own output", *outDir, *inDir)
+	}
+
+	// the func map and the -t/--template-dir template set are the same for
+	// every file in the tree, so build them once and Clone() per file below
+	// instead of redoing plugin loads / func-exec registration / glob+parse
+	// on each visit
+	base := template.New("gott-dir-base").Funcs(buildFuncMap())
+	if base, err = parseTemplateDirs(base); err != nil {
+		fatalf("%w", err)
+	}
+
+	err = filepath.WalkDir(*inDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(*inDir, path)
+		if err != nil {
+			return fmt.Errorf("error computing relative path for %q: %w", path, err)
+		}
+		outRel := rel
+		if *suffix != "" {
+			outRel = strings.TrimSuffix(outRel, *suffix)
+		}
+		outPath := filepath.Join(*outDir, outRel)
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading %q: %w", path, err)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+			return fmt.Errorf("error creating directory for %q: %w", outPath, err)
+		}
+
+		if !utf8.Valid(data) {
+			// binary file, pass through unchanged
+			return os.WriteFile(outPath, data, 0o644)
+		}
+
+		tpl, err := base.Clone()
+		if err != nil {
+			return fmt.Errorf("error cloning base template for %q: %w", path, err)
+		}
+		if tpl, err = tpl.New(filepath.Base(path)).Parse(string(data)); err != nil {
+			return fmt.Errorf("error parsing template %q: %w", path, err)
+		}
+		if *strictFlag {
+			tpl = tpl.Option("missingkey=error")
+		}
+
+		f, err := os.Create(outPath)
+		if err != nil {
+			return fmt.Errorf("error creating file %q: %w", outPath, err)
+		}
+		defer f.Close()
+
+		if err := tpl.Execute(f, context); err != nil {
+			return fmt.Errorf("error executing template %q: %w", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		fatalf("error rendering %q: %w", *inDir, err)
+	}
+}
+
 func main() {
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), "Usage:\n")
@@ -142,13 +567,16 @@ func main() {
 		fmt.Fprintf(flag.CommandLine.Output(), "\nVariable types:\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  bool    - boolean, uses Go's strconv.ParseBool\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  env     - string, read value from environment variable (chainable)\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  exec    - string, run input as a shell command and capture stdout (chainable)\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  file    - string, read value from utf-8 file (chainable)\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  float   - float64, uses Go's strconv.ParseFloat\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  float64 - float64, uses Go's strconv.ParseFloat\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  http    - string, GET the input URL and capture the response body, see --http-timeout (chainable)\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  int     - int64, uses Go's strconv.ParseInt\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  int64   - int64, uses Go's strconv.ParseInt\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  json    - any, uses Go's encoding/json.Unmarshal\n")
-		fmt.Fprintf(flag.CommandLine.Output(), "  string  - string\n\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  string  - string\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  vault   - string, read \"path#field\" from Vault, configured via VAULT_ADDR/VAULT_TOKEN (chainable)\n\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "Variable definition examples:\n")
 		fmt.Fprintf(flag.CommandLine.Output(), `  -d 'name=John'                         - define "name" string variable with "John" as value`+"\n")
 		fmt.Fprintf(flag.CommandLine.Output(), `  -d 'debug:bool=false'                  - define "debug" boolean variable with false as value`+"\n")
@@ -156,7 +584,24 @@ func main() {
 		fmt.Fprintf(flag.CommandLine.Output(), `  -d 'IsRelease:bool:env=IS_RELEASE"     - read environment variable "IS_RELEASE", parse it as bool, save the result to "IsRelease" variable`+"\n")
 		fmt.Fprintf(flag.CommandLine.Output(), `  -d 'a=1' -d 'b=2'                      - define multiple variables`+"\n\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  It's easiest to read it from right to left: NAME:A:B=VALUE - VALUE is applied to type B, then to type A, then saved as NAME.\n")
-		fmt.Fprintf(flag.CommandLine.Output(), "  Variables are available from the top level template context object, e.g. {{ if .IsRelease }}RELEASE{{ else }}DEBUG{{ end }}\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  Variables are available from the top level template context object, e.g. {{ if .IsRelease }}RELEASE{{ else }}DEBUG{{ end }}\n\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "Values files:\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  -V/--values can be repeated to merge whole maps of variables from YAML/JSON/TOML files into the context.\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  The format is detected from the file extension, or given explicitly with a FORMAT: prefix, e.g. -V yaml:values.txt\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  -d definitions are applied after values files and take precedence over them.\n\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "Multi-file templates:\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  -t/--template-dir can be repeated to parse every *.tmpl file in a directory into the same template set as -f.\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  Use -n/--name to pick which named template ({{ define \"name\" }}) is executed instead of the one parsed from -f.\n\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "Directory mode:\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --in-dir DIR --out-dir DIR renders every file in DIR as a template and writes it to the mirrored path in --out-dir, instead of a single -f/-o.\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --suffix .tmpl strips that suffix from output file names, e.g. foo.yaml.tmpl becomes foo.yaml.\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  Files with invalid utf-8 are treated as binary and copied through unchanged.\n\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "Custom template functions:\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --func-plugin path/to.so loads a Go plugin exporting a Funcs() template.FuncMap (repeatable).\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --func-exec name=cmd registers a function \"name\" that runs cmd, passing its args as JSON on stdin and reading the result from stdout, parsed as JSON if possible (repeatable).\n\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "Validation:\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --strict fails the render if the template references a context key that isn't set, instead of silently rendering <no value>.\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --schema path/to/schema.json validates the assembled context against a JSON Schema before the template is executed.\n")
 	}
 	flag.Parse()
 
@@ -165,6 +610,20 @@ func main() {
 		return
 	}
 
+	if *inDir != "" || *outDir != "" {
+		if *inDir == "" || *outDir == "" {
+			fatalf("--in-dir and --out-dir must be used together")
+		}
+		if *inputFile != "-" || *outputFile != "-" {
+			fatalf("--in-dir/--out-dir cannot be combined with -f/-o")
+		}
+		if templateName != "" {
+			fatalf("-n/--name cannot be combined with --in-dir/--out-dir: each file in the tree is its own template")
+		}
+		renderDirTree(buildContext())
+		return
+	}
+
 	// load template file data
 	var data []byte
 	var err error
@@ -184,21 +643,22 @@ func main() {
 	if !utf8.Valid(data) {
 		fatalf("template file %q contains invalid utf-8", *inputFile)
 	}
-	tpl, err := template.New("main").Funcs(sprig.TxtFuncMap()).Parse(string(data))
+	tpl, err := template.New("main").Funcs(buildFuncMap()).Parse(string(data))
 	if err != nil {
 		fatalf("error parsing template %q: %w", *inputFile, err)
 	}
 
-	// parse and apply variable definitions
-	context := map[string]interface{}{}
-	for _, vd := range varDefs {
-		name, val, err := parseVariableDefinition(string(vd))
-		if err != nil {
-			fatalf("error parsing variable definition %q: %w", vd, err)
-		}
-		context[name] = val
+	// parse every *.tmpl file from each -t/--template-dir into the same
+	// template set, so they can reference each other via {{ template }}/{{ block }}
+	if tpl, err = parseTemplateDirs(tpl); err != nil {
+		fatalf("%w", err)
+	}
+	if *strictFlag {
+		tpl = tpl.Option("missingkey=error")
 	}
 
+	context := buildContext()
+
 	var output io.Writer
 	if *outputFile == "-" {
 		output = os.Stdout
@@ -210,7 +670,12 @@ func main() {
 		defer f.Close()
 		output = f
 	}
-	if err := tpl.Execute(output, context); err != nil {
+	if templateName != "" {
+		err = tpl.ExecuteTemplate(output, templateName, context)
+	} else {
+		err = tpl.Execute(output, context)
+	}
+	if err != nil {
 		fatalf("failed executing template: %w", err)
 	}
 }